@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ScrapeState is checkpointed to --state-file after every successfully
+// scraped page, so an interrupted long-running scrape (e.g. -n 5000) can
+// resume with --resume instead of re-fetching pages it already has.
+type ScrapeState struct {
+	Country         string   `json:"country"`
+	LastPage        int      `json:"last_page"`
+	HotelsCollected int      `json:"hotels_collected"`
+	SeenHashes      []string `json:"seen_hashes"`
+}
+
+// hotelHash identifies a hotel by name+location so the same listing seen
+// across resumed runs is only counted once.
+func hotelHash(hotel Hotel) string {
+	sum := sha256.Sum256([]byte(hotel.Name + "|" + hotel.Location))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadScrapeState reads a checkpoint file. A missing file is not an error —
+// it just means this is the first run, so a zero-value state is returned.
+func loadScrapeState(path string) (*ScrapeState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ScrapeState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file %s: %v", path, err)
+	}
+
+	var state ScrapeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %v", path, err)
+	}
+
+	return &state, nil
+}
+
+// saveScrapeState writes state to path atomically: it writes a temp file in
+// the same directory, then renames it over the destination, so a crash
+// mid-write never leaves a corrupt checkpoint behind.
+func saveScrapeState(path string, state *ScrapeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating state dir %s: %v", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp state file: %v", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing temp state file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("closing temp state file: %v", err)
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// partialCSVPath is the sidecar CSV a state file uses to persist the hotels
+// collected so far, so a resumed run can seed Scraper.hotels without
+// re-scraping.
+func partialCSVPath(stateFile string) string {
+	ext := filepath.Ext(stateFile)
+	return stateFile[:len(stateFile)-len(ext)] + ".partial.csv"
+}
+
+// loadPartialCSV reads back a CSV written by CSVOutputter. Missing files
+// yield an empty slice rather than an error, matching loadScrapeState.
+func loadPartialCSV(path string) ([]Hotel, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading partial results %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing partial results %s: %v", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	hotels := make([]Hotel, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		stars, _ := strconv.Atoi(field(row, "Stars"))
+		lat, _ := strconv.ParseFloat(field(row, "Latitude"), 64)
+		lon, _ := strconv.ParseFloat(field(row, "Longitude"), 64)
+
+		hotels = append(hotels, Hotel{
+			Name:          field(row, "Name"),
+			Location:      field(row, "Location"),
+			Price:         field(row, "Price"),
+			TotalPrice:    field(row, "TotalPrice"),
+			PricePerNight: field(row, "PricePerNight"),
+			Rating:        field(row, "Rating"),
+			ReviewCount:   field(row, "ReviewCount"),
+			Stars:         stars,
+			RoomType:      field(row, "RoomType"),
+			Latitude:      lat,
+			Longitude:     lon,
+			CountryCode:   field(row, "CountryCode"),
+			City:          field(row, "City"),
+		})
+	}
+
+	return hotels, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestHotelHashStableAndDistinct(t *testing.T) {
+	a := Hotel{Name: "Grand Hotel", Location: "123 Main St"}
+	b := Hotel{Name: "Grand Hotel", Location: "123 Main St"}
+	c := Hotel{Name: "Grand Hotel", Location: "456 Other St"}
+
+	if hotelHash(a) != hotelHash(b) {
+		t.Error("hotelHash differs for identical name+location")
+	}
+	if hotelHash(a) == hotelHash(c) {
+		t.Error("hotelHash collides for different locations")
+	}
+}
+
+func TestPartialCSVPath(t *testing.T) {
+	got := partialCSVPath("state.json")
+	want := "state.partial.csv"
+	if got != want {
+		t.Errorf("partialCSVPath(%q) = %q, want %q", "state.json", got, want)
+	}
+}
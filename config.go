@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the optional YAML configuration for a scrape run. It lets the
+// page interaction script (and fetcher choice) live outside scrapePage so
+// Booking.com's "Load more results" button, consent banner, etc. can be
+// retuned without a rebuild.
+type Config struct {
+	UseStaticFetcher bool          `yaml:"use_static_fetcher"`
+	Interactions     []Interaction `yaml:"interactions"`
+}
+
+// LoadConfig reads and parses a YAML config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %v", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %v", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// defaultInteractions mirrors Booking.com's current page flow when no config
+// file is supplied: dismiss the sign-in prompt if it is shown, wait for the
+// property cards (and their lazy-loaded prices) to appear, scroll once, then
+// pause two seconds to settle any remaining lazy content.
+func defaultInteractions() []Interaction {
+	return []Interaction{
+		{Type: InteractionClick, Selector: `button[aria-label="Dismiss sign-in info."]`, Count: 1, Optional: true},
+		{Type: InteractionWaitForSelector, Selector: `div[data-testid="property-card"]`},
+		{Type: InteractionScroll, Count: 1},
+		{Type: InteractionSleep, Delay: 2 * time.Second},
+	}
+}
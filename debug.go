@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// Debug toggles per-page artifact capture (HTML snapshots, screenshots) and
+// switches the process logger from colorized text to structured JSON lines,
+// so a selector change on Booking.com's end leaves something reproducible to
+// debug instead of a silent fallback.
+var Debug bool
+
+// logger is the process-wide structured logger. It defaults to the
+// colorized console output callers have always seen; main() swaps it for a
+// JSON logger when --debug is set.
+var logger = slog.New(newColorHandler(os.Stdout))
+
+// newLogger builds the logger appropriate for the current Debug setting. The
+// JSON handler is given an explicit Debug level so the same status messages
+// that always render in color normally (colorHandler.Enabled ignores level
+// entirely) aren't silently dropped by slog's default Info floor.
+func newLogger(w io.Writer) *slog.Logger {
+	if Debug {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return slog.New(newColorHandler(w))
+}
+
+// colorHandler renders each slog record as one colored line, picking the
+// color by level (red for errors, yellow for warnings, green for info, cyan
+// for debug) and appending the record's attributes as key=value pairs.
+type colorHandler struct {
+	w io.Writer
+}
+
+func newColorHandler(w io.Writer) *colorHandler {
+	return &colorHandler{w: w}
+}
+
+func (h *colorHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *colorHandler) Handle(_ context.Context, record slog.Record) error {
+	printer := color.New(levelColor(record.Level))
+
+	msg := record.Message
+	record.Attrs(func(a slog.Attr) bool {
+		msg += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+		return true
+	})
+
+	_, err := printer.Fprintln(h.w, msg)
+	return err
+}
+
+func (h *colorHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *colorHandler) WithGroup(string) slog.Handler      { return h }
+
+func levelColor(level slog.Level) color.Attribute {
+	switch {
+	case level >= slog.LevelError:
+		return color.FgRed
+	case level >= slog.LevelWarn:
+		return color.FgYellow
+	case level >= slog.LevelInfo:
+		return color.FgGreen
+	default:
+		return color.FgCyan
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/context"
+)
+
+// InteractionType identifies a single scripted step a DynamicFetcher performs
+// on a page after it navigates there.
+type InteractionType string
+
+const (
+	InteractionClick           InteractionType = "click"
+	InteractionWaitForSelector InteractionType = "wait_for_selector"
+	InteractionScroll          InteractionType = "scroll"
+	InteractionSleep           InteractionType = "sleep"
+	InteractionPressKey        InteractionType = "press_key"
+)
+
+// Interaction describes one scripted page action, e.g. dismissing a consent
+// banner or clicking "Load more results" a fixed number of times. Interactions
+// run in order after Navigate and are typically sourced from a YAML config.
+type Interaction struct {
+	Type     InteractionType `yaml:"type"`
+	Selector string          `yaml:"selector,omitempty"`
+	Count    int             `yaml:"count,omitempty"`
+	Delay    time.Duration   `yaml:"delay,omitempty"`
+	// Optional marks a click as a no-op instead of an error when Selector
+	// never appears, e.g. a consent banner that's already been dismissed by
+	// a cookie from a previous page. Without this, a bare chromedp.Click
+	// blocks until the whole Fetch times out and aborts every action after
+	// it, including the ones that actually matter.
+	Optional bool `yaml:"optional,omitempty"`
+}
+
+// FetchOpts configures a single Fetcher.Fetch call. ScreenshotPath is only
+// honored by fetchers backed by a real browser; StaticFetcher ignores it.
+type FetchOpts struct {
+	Interactions   []Interaction
+	Timeout        time.Duration
+	ScreenshotPath string
+}
+
+// Fetcher retrieves the rendered HTML for a URL. StaticFetcher is cheap and
+// fast for pages that don't need JS; DynamicFetcher drives a real browser for
+// pages that do (infinite scroll, "Load more" buttons, lazy-loaded prices).
+type Fetcher interface {
+	Fetch(url string, opts FetchOpts) (string, error)
+	Name() string
+}
+
+// StaticFetcher fetches a page with a plain HTTP GET. It's a drop-in
+// replacement for DynamicFetcher on search result pages that render
+// everything server-side.
+type StaticFetcher struct {
+	client *http.Client
+}
+
+func NewStaticFetcher() *StaticFetcher {
+	return &StaticFetcher{client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (f *StaticFetcher) Fetch(url string, opts FetchOpts) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request: %v", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response body: %v", err)
+	}
+
+	return string(body), nil
+}
+
+func (f *StaticFetcher) Name() string { return "static" }
+
+// DynamicFetcher renders a page in a headless browser and runs a scripted
+// list of Interactions against it before capturing the HTML.
+type DynamicFetcher struct {
+	ctx context.Context
+}
+
+func NewDynamicFetcher(ctx context.Context) *DynamicFetcher {
+	return &DynamicFetcher{ctx: ctx}
+}
+
+func (f *DynamicFetcher) Fetch(url string, opts FetchOpts) (string, error) {
+	ctx, cancel := chromedp.NewContext(f.ctx)
+	defer cancel()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 45 * time.Second
+	}
+	ctx, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	actions := []chromedp.Action{
+		chromedp.EmulateViewport(1920, 1080),
+		chromedp.Navigate(url),
+	}
+	for _, interaction := range opts.Interactions {
+		actions = append(actions, interaction.actions()...)
+	}
+
+	var htmlContent string
+	var screenshot []byte
+	actions = append(actions, chromedp.OuterHTML("html", &htmlContent))
+	if opts.ScreenshotPath != "" {
+		actions = append(actions, chromedp.FullScreenshot(&screenshot, 90))
+	}
+
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return "", fmt.Errorf("navigation error: %v", err)
+	}
+
+	if opts.ScreenshotPath != "" {
+		if err := os.WriteFile(opts.ScreenshotPath, screenshot, 0o644); err != nil {
+			return "", fmt.Errorf("writing screenshot %s: %v", opts.ScreenshotPath, err)
+		}
+	}
+
+	return htmlContent, nil
+}
+
+func (f *DynamicFetcher) Name() string { return "dynamic" }
+
+// optionalClick clicks selector if (and only if) it's present when this step
+// runs, and is silent otherwise; the lookup does not wait for the node to
+// appear, and the click is bounded by a 3-second timeout. Unlike
+// chromedp.Click — which polls until visible with no timeout of its own —
+// this never blocks a Fetch call waiting on a banner that Booking.com only
+// shows sometimes.
+func optionalClick(selector string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		waitCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+
+		var nodes []*cdp.Node
+		if err := chromedp.Nodes(selector, &nodes, chromedp.ByQuery, chromedp.AtLeast(0)).Do(waitCtx); err != nil || len(nodes) == 0 {
+			return nil
+		}
+
+		_ = chromedp.Click(selector, chromedp.ByQuery).Do(waitCtx)
+		return nil
+	})
+}
+
+// actions expands an Interaction into one or more chromedp actions. Click and
+// scroll repeat Count times (minimum 1), pausing Delay after each repetition,
+// so e.g. clicking "Load more results" N times is a single Interaction.
+func (i Interaction) actions() []chromedp.Action {
+	count := i.Count
+	if count < 1 {
+		count = 1
+	}
+
+	var actions []chromedp.Action
+	switch i.Type {
+	case InteractionClick:
+		for n := 0; n < count; n++ {
+			if i.Optional {
+				actions = append(actions, optionalClick(i.Selector))
+			} else {
+				actions = append(actions, chromedp.Click(i.Selector, chromedp.ByQuery))
+			}
+			if i.Delay > 0 {
+				actions = append(actions, chromedp.Sleep(i.Delay))
+			}
+		}
+	case InteractionWaitForSelector:
+		actions = append(actions, chromedp.WaitVisible(i.Selector, chromedp.ByQuery))
+	case InteractionScroll:
+		for n := 0; n < count; n++ {
+			actions = append(actions, chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil))
+			if i.Delay > 0 {
+				actions = append(actions, chromedp.Sleep(i.Delay))
+			}
+		}
+	case InteractionSleep:
+		delay := i.Delay
+		if delay == 0 {
+			delay = time.Second
+		}
+		actions = append(actions, chromedp.Sleep(delay))
+	case InteractionPressKey:
+		actions = append(actions, chromedp.KeyEvent(i.Selector))
+	}
+
+	return actions
+}
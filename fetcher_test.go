@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestInteractionActionsClickCountAndDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		i     Interaction
+		count int // number of actions expected
+	}{
+		{"zero count defaults to one", Interaction{Type: InteractionClick, Selector: "#a"}, 1},
+		{"count with no delay", Interaction{Type: InteractionClick, Selector: "#a", Count: 3}, 3},
+		{"count with delay interleaves sleeps", Interaction{Type: InteractionClick, Selector: "#a", Count: 3, Delay: time.Second}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := tt.i.actions()
+			if len(actions) != tt.count {
+				t.Errorf("actions() = %d actions, want %d", len(actions), tt.count)
+			}
+		})
+	}
+}
+
+func TestInteractionActionsOptionalClickUsesOptionalClickHelper(t *testing.T) {
+	required := Interaction{Type: InteractionClick, Selector: "#a"}.actions()
+	optional := Interaction{Type: InteractionClick, Selector: "#a", Optional: true}.actions()
+
+	if len(required) != 1 || len(optional) != 1 {
+		t.Fatalf("got %d required, %d optional actions, want 1 each", len(required), len(optional))
+	}
+
+	if _, ok := optional[0].(chromedp.ActionFunc); !ok {
+		t.Errorf("optional click action is %T, want chromedp.ActionFunc (from optionalClick)", optional[0])
+	}
+	if _, ok := required[0].(chromedp.ActionFunc); ok {
+		t.Errorf("required click action is chromedp.ActionFunc, want a plain chromedp.Click action")
+	}
+}
+
+func TestInteractionActionsScroll(t *testing.T) {
+	actions := Interaction{Type: InteractionScroll, Count: 2, Delay: time.Second}.actions()
+	if len(actions) != 4 {
+		t.Errorf("actions() = %d, want 4 (2 scrolls + 2 sleeps)", len(actions))
+	}
+}
+
+func TestInteractionActionsSingleStepTypes(t *testing.T) {
+	for _, typ := range []InteractionType{InteractionWaitForSelector, InteractionSleep, InteractionPressKey} {
+		// Count shouldn't matter for single-step interaction types.
+		actions := Interaction{Type: typ, Selector: "#a", Count: 5}.actions()
+		if len(actions) != 1 {
+			t.Errorf("%s: actions() = %d, want 1", typ, len(actions))
+		}
+	}
+}
+
+func TestInteractionActionsUnknownTypeYieldsNone(t *testing.T) {
+	actions := Interaction{Type: "bogus"}.actions()
+	if len(actions) != 0 {
+		t.Errorf("actions() = %d, want 0 for an unrecognized type", len(actions))
+	}
+}
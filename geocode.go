@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// GeocodeResult is what a Geocoder resolves a raw address string to.
+type GeocodeResult struct {
+	Latitude    float64
+	Longitude   float64
+	CountryCode string
+	City        string
+}
+
+// Geocoder resolves a free-text address into coordinates and administrative
+// details.
+type Geocoder interface {
+	Geocode(address string) (GeocodeResult, error)
+}
+
+// geocodeCache memoizes Geocoder lookups to a JSON file keyed on the raw
+// address, so re-running a scrape against the same addresses costs nothing
+// and Nominatim's rate limit only has to be paid once per address ever seen.
+type geocodeCache struct {
+	path    string
+	mutex   sync.Mutex
+	entries map[string]GeocodeResult
+}
+
+func loadGeocodeCache(path string) (*geocodeCache, error) {
+	cache := &geocodeCache{path: path, entries: make(map[string]GeocodeResult)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading geocode cache %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("parsing geocode cache %s: %v", path, err)
+	}
+
+	return cache, nil
+}
+
+func (c *geocodeCache) get(address string) (GeocodeResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	result, ok := c.entries[address]
+	return result, ok
+}
+
+func (c *geocodeCache) put(address string, result GeocodeResult) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[address] = result
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding geocode cache: %v", err)
+	}
+
+	if err := os.MkdirAll(dirOf(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating geocode cache dir: %v", err)
+	}
+
+	return os.WriteFile(c.path, data, 0o644)
+}
+
+func dirOf(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[:i]
+		}
+	}
+	return "."
+}
+
+// CachingGeocoder wraps a Geocoder with the on-disk cache, so callers always
+// go through this instead of hitting the underlying Geocoder directly.
+type CachingGeocoder struct {
+	geocoder Geocoder
+	cache    *geocodeCache
+}
+
+// NewCachingGeocoder loads (or creates) the cache file at cachePath and wraps
+// geocoder with it.
+func NewCachingGeocoder(geocoder Geocoder, cachePath string) (*CachingGeocoder, error) {
+	cache, err := loadGeocodeCache(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	return &CachingGeocoder{geocoder: geocoder, cache: cache}, nil
+}
+
+func (g *CachingGeocoder) Geocode(address string) (GeocodeResult, error) {
+	if result, ok := g.cache.get(address); ok {
+		return result, nil
+	}
+
+	result, err := g.geocoder.Geocode(address)
+	if err != nil {
+		return GeocodeResult{}, err
+	}
+
+	if err := g.cache.put(address, result); err != nil {
+		return GeocodeResult{}, err
+	}
+
+	return result, nil
+}
+
+// NominatimGeocoder resolves addresses with OpenStreetMap's Nominatim, which
+// requires no API key but caps requests at 1/sec.
+type NominatimGeocoder struct {
+	client      *http.Client
+	lastRequest time.Time
+	mutex       sync.Mutex
+}
+
+func NewNominatimGeocoder() *NominatimGeocoder {
+	return &NominatimGeocoder{client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (g *NominatimGeocoder) Geocode(address string) (GeocodeResult, error) {
+	g.throttle()
+
+	endpoint := fmt.Sprintf("https://nominatim.openstreetmap.org/search?q=%s&format=json&addressdetails=1&limit=1", url.QueryEscape(address))
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GeocodeResult{}, fmt.Errorf("building nominatim request: %v", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return GeocodeResult{}, fmt.Errorf("nominatim request for %q: %v", address, err)
+	}
+	defer resp.Body.Close()
+
+	var results []struct {
+		Lat     string `json:"lat"`
+		Lon     string `json:"lon"`
+		Address struct {
+			CountryCode string `json:"country_code"`
+			City        string `json:"city"`
+			Town        string `json:"town"`
+			Village     string `json:"village"`
+		} `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return GeocodeResult{}, fmt.Errorf("decoding nominatim response for %q: %v", address, err)
+	}
+	if len(results) == 0 {
+		return GeocodeResult{}, fmt.Errorf("nominatim found no results for %q", address)
+	}
+
+	top := results[0]
+	city := top.Address.City
+	if city == "" {
+		city = top.Address.Town
+	}
+	if city == "" {
+		city = top.Address.Village
+	}
+
+	var lat, lon float64
+	fmt.Sscanf(top.Lat, "%f", &lat)
+	fmt.Sscanf(top.Lon, "%f", &lon)
+
+	return GeocodeResult{
+		Latitude:    lat,
+		Longitude:   lon,
+		CountryCode: top.Address.CountryCode,
+		City:        city,
+	}, nil
+}
+
+// throttle blocks until at least one second has passed since the previous
+// request, honoring Nominatim's usage policy.
+func (g *NominatimGeocoder) throttle() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if elapsed := time.Since(g.lastRequest); elapsed < time.Second {
+		time.Sleep(time.Second - elapsed)
+	}
+	g.lastRequest = time.Now()
+}
+
+// GoogleMapsGeocoder resolves addresses with the Google Maps Geocoding API.
+type GoogleMapsGeocoder struct {
+	client *http.Client
+	apiKey string
+}
+
+func NewGoogleMapsGeocoder(apiKey string) *GoogleMapsGeocoder {
+	return &GoogleMapsGeocoder{client: &http.Client{Timeout: 15 * time.Second}, apiKey: apiKey}
+}
+
+func (g *GoogleMapsGeocoder) Geocode(address string) (GeocodeResult, error) {
+	endpoint := fmt.Sprintf("https://maps.googleapis.com/maps/api/geocode/json?address=%s&key=%s", url.QueryEscape(address), url.QueryEscape(g.apiKey))
+
+	resp, err := g.client.Get(endpoint)
+	if err != nil {
+		return GeocodeResult{}, fmt.Errorf("google maps request for %q: %v", address, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status  string `json:"status"`
+		Results []struct {
+			Geometry struct {
+				Location struct {
+					Lat float64 `json:"lat"`
+					Lng float64 `json:"lng"`
+				} `json:"location"`
+			} `json:"geometry"`
+			AddressComponents []struct {
+				LongName  string   `json:"long_name"`
+				ShortName string   `json:"short_name"`
+				Types     []string `json:"types"`
+			} `json:"address_components"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return GeocodeResult{}, fmt.Errorf("decoding google maps response for %q: %v", address, err)
+	}
+	if body.Status != "OK" || len(body.Results) == 0 {
+		return GeocodeResult{}, fmt.Errorf("google maps found no results for %q (status %s)", address, body.Status)
+	}
+
+	top := body.Results[0]
+	result := GeocodeResult{
+		Latitude:  top.Geometry.Location.Lat,
+		Longitude: top.Geometry.Location.Lng,
+	}
+
+	for _, component := range top.AddressComponents {
+		for _, t := range component.Types {
+			switch t {
+			case "country":
+				result.CountryCode = component.ShortName
+			case "locality":
+				result.City = component.LongName
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// newGeocoder builds the Geocoder for the given --geocoder flag value.
+// GoogleMapsGeocoder reads its API key from GOOGLE_MAPS_API_KEY.
+func newGeocoder(name string) (Geocoder, error) {
+	switch name {
+	case "nominatim":
+		return NewNominatimGeocoder(), nil
+	case "google":
+		apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("GOOGLE_MAPS_API_KEY must be set to use the google geocoder")
+		}
+		return NewGoogleMapsGeocoder(apiKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported geocoder: %s", name)
+	}
+}
+
+// enrichHotels resolves each hotel's Location through geocoder, skipping
+// (and warning about) any address that fails rather than aborting the run.
+func enrichHotels(hotels []Hotel, geocoder Geocoder) {
+	for i := range hotels {
+		if hotels[i].Location == "" {
+			continue
+		}
+
+		result, err := geocoder.Geocode(hotels[i].Location)
+		if err != nil {
+			color.Yellow("Geocoding failed for %q: %v", hotels[i].Location, err)
+			continue
+		}
+
+		hotels[i].Latitude = result.Latitude
+		hotels[i].Longitude = result.Longitude
+		hotels[i].CountryCode = result.CountryCode
+		hotels[i].City = result.City
+	}
+}
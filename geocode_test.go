@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGeocodeCacheGetPutRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geocache.json")
+
+	cache, err := loadGeocodeCache(path)
+	if err != nil {
+		t.Fatalf("loadGeocodeCache: %v", err)
+	}
+
+	if _, ok := cache.get("123 Main St"); ok {
+		t.Fatal("get on empty cache returned a hit")
+	}
+
+	want := GeocodeResult{Latitude: 51.5, Longitude: -0.12, CountryCode: "gb", City: "London"}
+	if err := cache.put("123 Main St", want); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	if got, ok := cache.get("123 Main St"); !ok || got != want {
+		t.Fatalf("get after put = %+v, %v, want %+v, true", got, ok, want)
+	}
+
+	// A fresh load from disk should see the entry persisted by put.
+	reloaded, err := loadGeocodeCache(path)
+	if err != nil {
+		t.Fatalf("loadGeocodeCache after put: %v", err)
+	}
+	if got, ok := reloaded.get("123 Main St"); !ok || got != want {
+		t.Fatalf("reloaded get = %+v, %v, want %+v, true", got, ok, want)
+	}
+}
+
+func TestCachingGeocoderSkipsUnderlyingGeocoderOnCacheHit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geocache.json")
+
+	calls := 0
+	underlying := geocoderFunc(func(address string) (GeocodeResult, error) {
+		calls++
+		return GeocodeResult{City: "Paris"}, nil
+	})
+
+	geocoder, err := NewCachingGeocoder(underlying, path)
+	if err != nil {
+		t.Fatalf("NewCachingGeocoder: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := geocoder.Geocode("1 Rue de Rivoli"); err != nil {
+			t.Fatalf("Geocode: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("underlying geocoder called %d times, want 1", calls)
+	}
+}
+
+// geocoderFunc adapts a plain function to the Geocoder interface for tests.
+type geocoderFunc func(address string) (GeocodeResult, error)
+
+func (f geocoderFunc) Geocode(address string) (GeocodeResult, error) { return f(address) }
@@ -1,68 +1,103 @@
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/chromedp/chromedp"
-	"github.com/fatih/color"
 	"golang.org/x/net/context"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
 	"time"
 )
 
 type Hotel struct {
-	Name     string
-	Location string
-	Price    string
+	Name          string
+	Location      string
+	Price         string
+	TotalPrice    string  `json:"TotalPrice,omitempty"`
+	PricePerNight string  `json:"PricePerNight,omitempty"`
+	Rating        string  `json:"Rating,omitempty"`
+	ReviewCount   string  `json:"ReviewCount,omitempty"`
+	Stars         int     `json:"Stars,omitempty"`
+	RoomType      string  `json:"RoomType,omitempty"`
+	Latitude      float64 `json:"Latitude,omitempty"`
+	Longitude     float64 `json:"Longitude,omitempty"`
+	CountryCode   string  `json:"CountryCode,omitempty"`
+	City          string  `json:"City,omitempty"`
 }
 
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
 type Scraper struct {
-	baseURL   string
-	hotels    []Hotel
-	mutex     sync.Mutex
-	maxHotels int
+	baseURL     string
+	country     string
+	hotels      []Hotel
+	mutex       sync.Mutex
+	maxHotels   int
+	workers     int
+	fetcher     Fetcher
+	fetchOpts   FetchOpts
+	rateLimiter *hostRateLimiter
+	resume      bool
+	stateFile   string
 }
 
-func NewScraper(country string, maxHotels int) *Scraper {
-	countryFormatted := strings.ReplaceAll(country, " ", "+")
-	baseURL := fmt.Sprintf("https://www.booking.com/searchresults.html?ss=%s&dest_type=country&nflt=&order=popularity", countryFormatted)
+func NewScraper(params SearchParams, maxHotels, workers int, rps float64, fetcher Fetcher, fetchOpts FetchOpts, resume bool, stateFile string) *Scraper {
+	if workers < 1 {
+		workers = 1
+	}
 
 	return &Scraper{
-		baseURL:   baseURL,
-		hotels:    make([]Hotel, 0),
-		maxHotels: maxHotels,
+		baseURL:     buildBookingURL(params),
+		country:     params.Country,
+		hotels:      make([]Hotel, 0),
+		maxHotels:   maxHotels,
+		workers:     workers,
+		fetcher:     fetcher,
+		fetchOpts:   fetchOpts,
+		rateLimiter: newHostRateLimiter(rps),
+		resume:      resume,
+		stateFile:   stateFile,
 	}
 }
 
 func (s *Scraper) scrapePage(ctx context.Context, pageNum int) ([]Hotel, error) {
+	start := time.Now()
 	var hotels []Hotel
 
 	url := fmt.Sprintf("%s&offset=%d", s.baseURL, pageNum*25)
-	color.Blue("Accessing URL: %s", url)
-
-	ctx, cancel := chromedp.NewContext(ctx)
-	defer cancel()
-
-	ctx, cancel = context.WithTimeout(ctx, 45*time.Second)
-	defer cancel()
-
-	var htmlContent string
-	err := chromedp.Run(ctx,
-		chromedp.EmulateViewport(1920, 1080),
-		chromedp.Navigate(url),
-		chromedp.WaitVisible(`div[data-testid="property-card"]`, chromedp.ByQuery),
-		chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
-		chromedp.Sleep(2*time.Second),
-		chromedp.OuterHTML("html", &htmlContent),
-	)
+	logger.Debug("accessing url", "url", url, "page", pageNum+1)
+
+	s.rateLimiter.wait(url)
 
+	fetcher := s.fetcher
+	if fetcher == nil {
+		fetcher = NewDynamicFetcher(ctx)
+	}
+
+	fetchOpts := s.fetchOpts
+	timestamp := start.Format("20060102T150405")
+	if Debug {
+		if err := os.MkdirAll("debug", 0o755); err != nil {
+			return nil, fmt.Errorf("creating debug dir: %v", err)
+		}
+		fetchOpts.ScreenshotPath = fmt.Sprintf("debug/page-%d-%s.png", pageNum+1, timestamp)
+	}
+
+	htmlContent, err := fetcher.Fetch(url, fetchOpts)
 	if err != nil {
-		return nil, fmt.Errorf("navigation error: %v", err)
+		return nil, err
+	}
+
+	if Debug {
+		htmlPath := fmt.Sprintf("debug/page-%d-%s.html", pageNum+1, timestamp)
+		if err := os.WriteFile(htmlPath, []byte(htmlContent), 0o644); err != nil {
+			return nil, fmt.Errorf("writing debug html %s: %v", htmlPath, err)
+		}
 	}
 
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
@@ -100,110 +135,238 @@ func (s *Scraper) scrapePage(ctx context.Context, pageNum int) ([]Hotel, error)
 			price = strings.TrimSpace(strings.ReplaceAll(price, "US$", ""))
 			hotel.Price = strings.ReplaceAll(price, ",", "")
 		}
+		hotel.TotalPrice = hotel.Price
+
+		// Extract per-night price, when Booking.com breaks it out separately
+		if perNight := s.Find("span[data-testid='price-per-night']").Text(); perNight != "" {
+			perNight = strings.TrimSpace(strings.ReplaceAll(perNight, "US$", ""))
+			hotel.PricePerNight = strings.ReplaceAll(perNight, ",", "")
+		}
+
+		// Extract rating and review count, e.g. "Scored 8.5 1,234 reviews"
+		if reviewScore := s.Find("div[data-testid='review-score']").Text(); reviewScore != "" {
+			hotel.Rating, hotel.ReviewCount = parseReviewScore(reviewScore)
+		}
+
+		// Extract star rating
+		hotel.Stars = s.Find("div[data-testid='rating-stars'] span").Length()
+
+		// Extract room type
+		if roomType := s.Find("span[data-testid='recommended-units']").Text(); roomType != "" {
+			hotel.RoomType = strings.TrimSpace(roomType)
+		}
 
 		if hotel.Name != "" {
 			hotels = append(hotels, hotel)
-			color.Green("Found hotel: %s", hotel.Name)
+			logger.Info("found hotel", "name", hotel.Name, "page", pageNum+1)
 		}
 	})
 
-	if len(hotels) > 0 {
-		color.Yellow("Found %d hotels on page %d", len(hotels), pageNum+1)
-	} else {
-		color.Red("No hotels found on page %d", pageNum+1)
-	}
+	logger.Info("scraped page",
+		"fetcher", fetcher.Name(),
+		"url", url,
+		"page", pageNum+1,
+		"hotels_found", len(hotels),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
 
 	return hotels, nil
 }
 
-func (s *Scraper) saveToCSV(filename string) error {
-	if len(s.hotels) == 0 {
-		return fmt.Errorf("no hotels found to save")
+// pageResult is what a worker sends back to the collector after scraping one
+// page.
+type pageResult struct {
+	page   int
+	hotels []Hotel
+	err    error
+}
+
+// checkpoint persists progress so far to s.stateFile and its sidecar partial
+// CSV. Failures are logged rather than aborting the scrape — a missed
+// checkpoint just costs a bit of re-work on the next --resume.
+func (s *Scraper) checkpoint(lastPage int, seenHashes map[string]bool) {
+	hashes := make([]string, 0, len(seenHashes))
+	for hash := range seenHashes {
+		hashes = append(hashes, hash)
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	state := &ScrapeState{
+		Country:         s.country,
+		LastPage:        lastPage,
+		HotelsCollected: len(s.hotels),
+		SeenHashes:      hashes,
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			println(err)
-		}
-	}(file)
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	if err := saveScrapeState(s.stateFile, state); err != nil {
+		logger.Error("failed to save checkpoint", "error", err)
+		return
+	}
 
-	if err := writer.Write([]string{"Name", "Location", "Price"}); err != nil {
-		return err
+	if err := (&CSVOutputter{path: partialCSVPath(s.stateFile)}).Write(s.hotels); err != nil {
+		logger.Error("failed to save partial results", "error", err)
 	}
+}
 
-	for _, hotel := range s.hotels {
-		if err := writer.Write([]string{hotel.Name, hotel.Location, hotel.Price}); err != nil {
+func (s *Scraper) Start() error {
+	ctx, stopNotify := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopNotify()
+
+	startPage := 0
+	seenHashes := make(map[string]bool)
+
+	if s.resume {
+		state, err := loadScrapeState(s.stateFile)
+		if err != nil {
 			return err
 		}
+
+		if state.LastPage > 0 || len(state.SeenHashes) > 0 {
+			seeded, err := loadPartialCSV(partialCSVPath(s.stateFile))
+			if err != nil {
+				return err
+			}
+			s.hotels = append(s.hotels, seeded...)
+			startPage = state.LastPage + 1
+			for _, hash := range state.SeenHashes {
+				seenHashes[hash] = true
+			}
+			logger.Debug("resuming scrape", "page", startPage+1, "hotels_collected", len(s.hotels))
+		}
 	}
 
-	return nil
-}
+	for _, hotel := range s.hotels {
+		seenHashes[hotelHash(hotel)] = true
+	}
 
-func (s *Scraper) Start() error {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("disable-notifications", true),
-		chromedp.Flag("disable-popup-blocking", true),
-		chromedp.Flag("ignore-certificate-errors", true),
-		chromedp.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
-	)
+	if s.fetcher == nil {
+		opts := append(chromedp.DefaultExecAllocatorOptions[:],
+			chromedp.Flag("headless", true),
+			chromedp.Flag("disable-gpu", true),
+			chromedp.Flag("no-sandbox", true),
+			chromedp.Flag("disable-dev-shm-usage", true),
+			chromedp.Flag("disable-web-security", true),
+			chromedp.Flag("disable-notifications", true),
+			chromedp.Flag("disable-popup-blocking", true),
+			chromedp.Flag("ignore-certificate-errors", true),
+			chromedp.UserAgent(userAgent),
+		)
+
+		allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
+		defer cancel()
+
+		browserCtx, cancel := chromedp.NewContext(allocCtx,
+			chromedp.WithLogf(log.Printf),
+		)
+		defer cancel()
+
+		s.fetcher = NewDynamicFetcher(browserCtx)
+	}
 
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	jobs := make(chan int)
+	results := make(chan pageResult)
+
+	var workerGroup sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		workerGroup.Add(1)
+		go func() {
+			defer workerGroup.Done()
+			for page := range jobs {
+				hotels, err := s.scrapePage(ctx, page)
+				select {
+				case results <- pageResult{page: page, hotels: hotels, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 
-	ctx, cancel := chromedp.NewContext(allocCtx,
-		chromedp.WithLogf(log.Printf),
-	)
-	defer cancel()
+	go func() {
+		defer close(jobs)
+		for page := startPage; ; page++ {
+			select {
+			case jobs <- page:
+			case <-jobCtx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workerGroup.Wait()
+		close(results)
+	}()
+
+	stopping := false
+	consecutiveEmpty := 0
+	// completedPages tracks out-of-order page completions from the worker
+	// pool. nextContiguous only advances while the page it's pointing at has
+	// completed, so the checkpoint watermark (nextContiguous-1) never jumps
+	// ahead of a page that's still in flight or failed.
+	completedPages := make(map[int]bool)
+	nextContiguous := startPage
+	for result := range results {
+		if stopping {
+			continue
+		}
 
-	page := 0
-	for len(s.hotels) < s.maxHotels {
-		color.Cyan("Scraping page %d...", page+1)
+		logger.Debug("collected page result", "page", result.page+1)
 
-		hotels, err := s.scrapePage(ctx, page)
-		if err != nil {
-			color.Red("Error scraping page %d: %v", page+1, err)
+		if result.err != nil {
+			logger.Error("error scraping page", "page", result.page+1, "error", result.err)
 			continue
 		}
 
-		if len(hotels) == 0 {
-			color.Yellow("No more hotels found on page %d. Stopping.", page+1)
-			break
+		completedPages[result.page] = true
+		for completedPages[nextContiguous] {
+			nextContiguous++
 		}
 
-		s.mutex.Lock()
+		if len(result.hotels) == 0 {
+			consecutiveEmpty++
+			logger.Warn("no hotels found on page", "page", result.page+1)
+			if consecutiveEmpty >= s.workers {
+				logger.Warn("no more hotels found, stopping")
+				stopping = true
+				stopJobs()
+			}
+			continue
+		}
+		consecutiveEmpty = 0
 
-		remainingSlots := s.maxHotels - len(s.hotels)
-		if remainingSlots > len(hotels) {
-			s.hotels = append(s.hotels, hotels...)
-		} else {
-			s.hotels = append(s.hotels, hotels[:remainingSlots]...)
+		s.mutex.Lock()
+		for _, hotel := range result.hotels {
+			if len(s.hotels) >= s.maxHotels {
+				break
+			}
+			hash := hotelHash(hotel)
+			if seenHashes[hash] {
+				continue
+			}
+			seenHashes[hash] = true
+			s.hotels = append(s.hotels, hotel)
 		}
 		currentCount := len(s.hotels)
 		s.mutex.Unlock()
 
-		color.Green("Total hotels found: %d/%d", currentCount, s.maxHotels)
+		logger.Info("total hotels found", "count", currentCount, "target", s.maxHotels)
+
+		if s.stateFile != "" && nextContiguous-1 >= startPage {
+			s.checkpoint(nextContiguous-1, seenHashes)
+		}
 
 		if currentCount >= s.maxHotels {
-			color.Yellow("Reached target number of hotels. Stopping.")
-			break
+			logger.Warn("reached target number of hotels, stopping")
+			stopping = true
+			stopJobs()
 		}
+	}
 
-		page++
-		time.Sleep(5 * time.Second)
+	if ctx.Err() != nil {
+		logger.Warn("interrupted, flushing hotels collected so far", "count", len(s.hotels))
 	}
 
 	if len(s.hotels) == 0 {
@@ -216,37 +379,115 @@ func (s *Scraper) Start() error {
 func main() {
 	// Define command line flags
 	maxHotels := flag.Int("n", 200, "Number of hotels to scrape")
+	configPath := flag.String("config", "", "Path to a YAML config file controlling page interactions")
+	static := flag.Bool("static", false, "Use a plain HTTP fetcher instead of a headless browser (faster, but won't run page interactions)")
+	format := flag.String("format", "csv", "Output format: csv|json|jsonl|sqlite|console")
+	flag.StringVar(format, "f", "csv", "Shorthand for --format")
+	resultsDir := flag.String("results-dir", "results", "Directory to write output files into")
+	compress := flag.Bool("compress", false, "Zip the results directory after scraping")
+	flag.BoolVar(compress, "cc", false, "Shorthand for --compress")
+	geocoderName := flag.String("geocoder", "", "Geocode hotel locations using this provider: nominatim|google")
+	workers := flag.Int("workers", 4, "Number of pages to fetch concurrently")
+	rps := flag.Float64("rps", 1, "Maximum requests per second per host")
+	debug := flag.Bool("debug", false, "Write per-page HTML/screenshot artifacts to debug/ and switch logging to JSON lines")
+	checkIn := flag.String("checkin", "", "Check-in date (YYYY-MM-DD)")
+	checkOut := flag.String("checkout", "", "Check-out date (YYYY-MM-DD)")
+	adults := flag.Int("adults", 2, "Number of adult guests")
+	children := flag.Int("children", 0, "Number of child guests")
+	rooms := flag.Int("rooms", 1, "Number of rooms")
+	minPrice := flag.Int("min-price", 0, "Minimum total price (USD)")
+	maxPrice := flag.Int("max-price", 0, "Maximum total price (USD)")
+	var stars starsFlag
+	flag.Var(&stars, "stars", "Star rating to include (repeatable, e.g. -stars 4 -stars 5)")
+	propertyType := flag.String("property-type", "", "Property type: hotel|apartment|hostel")
+	resume := flag.Bool("resume", false, "Resume an interrupted scrape from --state-file")
+	stateFile := flag.String("state-file", "state.json", "Checkpoint file used by --resume")
 	flag.Parse()
 
+	Debug = *debug
+	logger = newLogger(os.Stdout)
+
 	// Get the country from remaining arguments
 	args := flag.Args()
 	if len(args) < 1 {
-		color.Red("Please provide a country name")
-		color.Yellow("Usage: go run main.go [-n number_of_hotels] \"country name\"")
-		color.Yellow("Example: go run main.go -n 300 \"United States\"")
+		logger.Error("missing required argument: country name")
+		logger.Warn(`usage: go run . [-n number_of_hotels] "country name"`)
+		logger.Warn(`example: go run . -n 300 "United States"`)
 		os.Exit(1)
 	}
 
 	country := args[0]
-	outputFile := strings.ToLower(strings.ReplaceAll(country, " ", "_")) + "_hotels.csv"
+	baseName := strings.ToLower(strings.ReplaceAll(country, " ", "_")) + "_hotels"
 
-	color.Cyan("Starting booking.com scraper")
-	color.Cyan("Country: %s", country)
-	color.Cyan("Target number of hotels: %d", *maxHotels)
-	color.Cyan("Output file: %s", outputFile)
+	logger.Debug("starting booking.com scraper", "country", country, "target_hotels", *maxHotels, "format", *format)
 
-	scraper := NewScraper(country, *maxHotels)
+	interactions := defaultInteractions()
+	useStatic := *static
+	if *configPath != "" {
+		cfg, err := LoadConfig(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		interactions = cfg.Interactions
+		useStatic = useStatic || cfg.UseStaticFetcher
+	}
+
+	var fetcher Fetcher
+	if useStatic {
+		fetcher = NewStaticFetcher()
+	}
+
+	params := SearchParams{
+		Country:      country,
+		CheckIn:      *checkIn,
+		CheckOut:     *checkOut,
+		Adults:       *adults,
+		Children:     *children,
+		Rooms:        *rooms,
+		MinPrice:     *minPrice,
+		MaxPrice:     *maxPrice,
+		Stars:        stars,
+		PropertyType: PropertyType(*propertyType),
+	}
+
+	scraper := NewScraper(params, *maxHotels, *workers, *rps, fetcher, FetchOpts{Interactions: interactions}, *resume, *stateFile)
 
 	start := time.Now()
 	if err := scraper.Start(); err != nil {
 		log.Fatal(err)
 	}
 
-	if err := scraper.saveToCSV(outputFile); err != nil {
+	if *geocoderName != "" {
+		geocoder, err := newGeocoder(*geocoderName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		cachingGeocoder, err := NewCachingGeocoder(geocoder, "cache/geocache.json")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logger.Debug("geocoding hotel locations", "count", len(scraper.hotels), "geocoder", *geocoderName)
+		enrichHotels(scraper.hotels, cachingGeocoder)
+	}
+
+	outputter, err := NewOutputter(OutputFormat(*format), *resultsDir, baseName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := outputter.Write(scraper.hotels); err != nil {
 		log.Fatal(err)
 	}
 
-	color.Green("✓ Scraping completed in %v", time.Since(start))
-	color.Green("✓ Total hotels scraped: %d", len(scraper.hotels))
-	color.Green("✓ Results saved to: %s", outputFile)
+	if *compress && OutputFormat(*format) != FormatConsole {
+		zipPath, err := compressResultsDir(*resultsDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.Info("results directory compressed", "path", zipPath)
+	}
+
+	logger.Info("scraping completed", "duration_ms", time.Since(start).Milliseconds(), "hotels_scraped", len(scraper.hotels))
 }
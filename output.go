@@ -0,0 +1,304 @@
+package main
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fatih/color"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OutputFormat identifies one of the supported Outputter implementations.
+type OutputFormat string
+
+const (
+	FormatCSV     OutputFormat = "csv"
+	FormatJSON    OutputFormat = "json"
+	FormatJSONL   OutputFormat = "jsonl"
+	FormatSQLite  OutputFormat = "sqlite"
+	FormatConsole OutputFormat = "console"
+)
+
+// Outputter writes a completed scrape to some destination. Every
+// implementation, JSONLOutputter included, receives the whole result set in
+// one Write call; JSONLOutputter encodes and writes one record at a time, so
+// the encoded output is never held in memory as a whole.
+type Outputter interface {
+	Write(hotels []Hotel) error
+}
+
+// NewOutputter builds the Outputter for the given format, writing to
+// baseName plus the format's extension inside resultsDir (resultsDir is
+// created if it doesn't exist). ConsoleOutputter ignores baseName and
+// resultsDir entirely.
+func NewOutputter(format OutputFormat, resultsDir, baseName string) (Outputter, error) {
+	if format == FormatConsole {
+		return &ConsoleOutputter{}, nil
+	}
+
+	if err := os.MkdirAll(resultsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating results dir %s: %v", resultsDir, err)
+	}
+
+	switch format {
+	case FormatCSV:
+		return &CSVOutputter{path: filepath.Join(resultsDir, baseName+".csv")}, nil
+	case FormatJSON:
+		return &JSONOutputter{path: filepath.Join(resultsDir, baseName+".json")}, nil
+	case FormatJSONL:
+		return &JSONLOutputter{path: filepath.Join(resultsDir, baseName+".jsonl")}, nil
+	case FormatSQLite:
+		return &SQLiteOutputter{path: filepath.Join(resultsDir, baseName+".sqlite")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// CSVOutputter writes hotels to a CSV file: a header row naming every Hotel
+// field (Name, Location and Price first), then one row per hotel.
+type CSVOutputter struct {
+	path string
+}
+
+func (o *CSVOutputter) Write(hotels []Hotel) error {
+	if len(hotels) == 0 {
+		return fmt.Errorf("no hotels found to save")
+	}
+
+	file, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{
+		"Name", "Location", "Price", "TotalPrice", "PricePerNight", "Rating", "ReviewCount", "Stars", "RoomType",
+		"Latitude", "Longitude", "CountryCode", "City",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, hotel := range hotels {
+		record := []string{
+			hotel.Name,
+			hotel.Location,
+			hotel.Price,
+			hotel.TotalPrice,
+			hotel.PricePerNight,
+			hotel.Rating,
+			hotel.ReviewCount,
+			strconv.Itoa(hotel.Stars),
+			hotel.RoomType,
+			strconv.FormatFloat(hotel.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(hotel.Longitude, 'f', -1, 64),
+			hotel.CountryCode,
+			hotel.City,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JSONOutputter writes the whole result set as a single pretty-printed JSON
+// array.
+type JSONOutputter struct {
+	path string
+}
+
+func (o *JSONOutputter) Write(hotels []Hotel) error {
+	if len(hotels) == 0 {
+		return fmt.Errorf("no hotels found to save")
+	}
+
+	file, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(hotels)
+}
+
+// JSONLOutputter writes one JSON object per line, encoding as it goes so
+// memory usage doesn't grow with the result set size.
+type JSONLOutputter struct {
+	path string
+}
+
+func (o *JSONLOutputter) Write(hotels []Hotel) error {
+	if len(hotels) == 0 {
+		return fmt.Errorf("no hotels found to save")
+	}
+
+	file, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, hotel := range hotels {
+		if err := encoder.Encode(hotel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SQLiteOutputter upserts hotels into a `hotels` table keyed on
+// (name, location), so re-running a scrape against the same database
+// refreshes existing rows instead of duplicating them.
+type SQLiteOutputter struct {
+	path string
+}
+
+func (o *SQLiteOutputter) Write(hotels []Hotel) error {
+	if len(hotels) == 0 {
+		return fmt.Errorf("no hotels found to save")
+	}
+
+	db, err := sql.Open("sqlite3", o.path)
+	if err != nil {
+		return fmt.Errorf("opening sqlite db %s: %v", o.path, err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS hotels (
+			name            TEXT NOT NULL,
+			location        TEXT NOT NULL,
+			price           TEXT,
+			total_price     TEXT,
+			price_per_night TEXT,
+			rating          TEXT,
+			review_count    TEXT,
+			stars           INTEGER,
+			room_type       TEXT,
+			latitude        REAL,
+			longitude       REAL,
+			country_code    TEXT,
+			city            TEXT,
+			PRIMARY KEY (name, location)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating hotels table: %v", err)
+	}
+
+	stmt, err := db.Prepare(`
+		INSERT INTO hotels (
+			name, location, price, total_price, price_per_night, rating, review_count, stars, room_type,
+			latitude, longitude, country_code, city
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name, location) DO UPDATE SET
+			price           = excluded.price,
+			total_price     = excluded.total_price,
+			price_per_night = excluded.price_per_night,
+			rating          = excluded.rating,
+			review_count    = excluded.review_count,
+			stars           = excluded.stars,
+			room_type       = excluded.room_type,
+			latitude        = excluded.latitude,
+			longitude       = excluded.longitude,
+			country_code    = excluded.country_code,
+			city            = excluded.city
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing upsert statement: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, hotel := range hotels {
+		_, err := stmt.Exec(
+			hotel.Name, hotel.Location, hotel.Price, hotel.TotalPrice, hotel.PricePerNight,
+			hotel.Rating, hotel.ReviewCount, hotel.Stars, hotel.RoomType,
+			hotel.Latitude, hotel.Longitude, hotel.CountryCode, hotel.City,
+		)
+		if err != nil {
+			return fmt.Errorf("upserting hotel %q: %v", hotel.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ConsoleOutputter prints hotels to stdout instead of writing a file. Handy
+// for quick one-off checks without leaving files behind.
+type ConsoleOutputter struct{}
+
+func (o *ConsoleOutputter) Write(hotels []Hotel) error {
+	if len(hotels) == 0 {
+		return fmt.Errorf("no hotels found to save")
+	}
+
+	for _, hotel := range hotels {
+		color.Green("%s | %s | %s", hotel.Name, hotel.Location, hotel.Price)
+	}
+
+	return nil
+}
+
+// compressResultsDir zips every file in dir into dir + ".zip" alongside it.
+func compressResultsDir(dir string) (string, error) {
+	zipPath := dir + ".zip"
+
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("creating zip %s: %v", zipPath, err)
+	}
+	defer zipFile.Close()
+
+	writer := zip.NewWriter(zipFile)
+	defer writer.Close()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("reading results dir %s: %v", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if err := addFileToZip(writer, dir, entry.Name()); err != nil {
+			return "", err
+		}
+	}
+
+	return zipPath, nil
+}
+
+func addFileToZip(writer *zip.Writer, dir, name string) error {
+	src, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("opening %s: %v", name, err)
+	}
+	defer src.Close()
+
+	dst, err := writer.Create(name)
+	if err != nil {
+		return fmt.Errorf("adding %s to zip: %v", name, err)
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var sampleHotels = []Hotel{
+	{Name: "Grand Hotel", Location: "Paris", Price: "120", Stars: 4, Latitude: 48.85, Longitude: 2.35, CountryCode: "fr", City: "Paris"},
+	{Name: "Budget Inn", Location: "Lyon", Price: "60", Stars: 2},
+}
+
+func TestCSVOutputterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotels.csv")
+
+	if err := (&CSVOutputter{path: path}).Write(sampleHotels); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := loadPartialCSV(path)
+	if err != nil {
+		t.Fatalf("loadPartialCSV: %v", err)
+	}
+	if len(got) != len(sampleHotels) {
+		t.Fatalf("got %d hotels, want %d", len(got), len(sampleHotels))
+	}
+	if got[0].Name != "Grand Hotel" || got[0].City != "Paris" {
+		t.Errorf("got[0] = %+v, want name/city Grand Hotel/Paris", got[0])
+	}
+}
+
+func TestJSONOutputterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotels.json")
+
+	if err := (&JSONOutputter{path: path}).Write(sampleHotels); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var got []Hotel
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(got) != len(sampleHotels) || got[0].Name != sampleHotels[0].Name {
+		t.Errorf("got %+v, want %+v", got, sampleHotels)
+	}
+}
+
+func TestJSONLOutputterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotels.jsonl")
+
+	if err := (&JSONLOutputter{path: path}).Write(sampleHotels); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	var got []Hotel
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var hotel Hotel
+		if err := decoder.Decode(&hotel); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		got = append(got, hotel)
+	}
+
+	if len(got) != len(sampleHotels) || got[1].Name != sampleHotels[1].Name {
+		t.Errorf("got %+v, want %+v", got, sampleHotels)
+	}
+}
+
+func TestSQLiteOutputterUpsertsOnNameAndLocation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotels.sqlite")
+	outputter := &SQLiteOutputter{path: path}
+
+	if err := outputter.Write(sampleHotels); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	updated := sampleHotels[0]
+	updated.Price = "999"
+	if err := outputter.Write([]Hotel{updated}); err != nil {
+		t.Fatalf("Write (upsert): %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM hotels").Scan(&count); err != nil {
+		t.Fatalf("counting rows: %v", err)
+	}
+	if count != len(sampleHotels) {
+		t.Fatalf("row count = %d, want %d (upsert should not duplicate)", count, len(sampleHotels))
+	}
+
+	var price string
+	if err := db.QueryRow("SELECT price FROM hotels WHERE name = ? AND location = ?", updated.Name, updated.Location).Scan(&price); err != nil {
+		t.Fatalf("querying updated row: %v", err)
+	}
+	if price != "999" {
+		t.Errorf("price = %q, want %q", price, "999")
+	}
+}
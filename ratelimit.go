@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple requests-per-second limiter: it refills at rps
+// tokens/sec up to a burst of rps tokens and blocks callers until a token is
+// available.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rps    float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rps float64) *tokenBucket {
+	if rps <= 0 {
+		rps = 1
+	}
+	return &tokenBucket{rps: rps, tokens: rps, last: time.Now()}
+}
+
+// take blocks until a token is available, then consumes one.
+func (b *tokenBucket) take() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rps
+	if b.tokens > b.rps {
+		b.tokens = b.rps
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / b.rps * float64(time.Second))
+		time.Sleep(wait)
+		b.tokens = 0
+		b.last = time.Now()
+		return
+	}
+
+	b.tokens--
+}
+
+// hostRateLimiter keeps one tokenBucket per host so workers hitting
+// different hosts don't throttle each other, while concurrent requests to
+// the same host stay polite regardless of worker count.
+type hostRateLimiter struct {
+	mutex   sync.Mutex
+	rps     float64
+	buckets map[string]*tokenBucket
+}
+
+func newHostRateLimiter(rps float64) *hostRateLimiter {
+	return &hostRateLimiter{rps: rps, buckets: make(map[string]*tokenBucket)}
+}
+
+// wait blocks until rawURL's host is allowed to make another request.
+func (h *hostRateLimiter) wait(rawURL string) {
+	host := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	h.mutex.Lock()
+	bucket, ok := h.buckets[host]
+	if !ok {
+		bucket = newTokenBucket(h.rps)
+		h.buckets[host] = bucket
+	}
+	h.mutex.Unlock()
+
+	bucket.take()
+}
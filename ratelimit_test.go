@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurstThenRefill(t *testing.T) {
+	bucket := newTokenBucket(10) // burst of 10, refills at 10/sec
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		bucket.take()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("burst of 10 took %v, want near-instant", elapsed)
+	}
+
+	// The bucket is now empty; the next take must wait ~1/rps seconds (100ms)
+	// for a token to refill.
+	start = time.Now()
+	bucket.take()
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("take after burst returned in %v, want it to wait for refill (~100ms)", elapsed)
+	}
+}
+
+func TestTokenBucketInvalidRPSDefaultsToOne(t *testing.T) {
+	bucket := newTokenBucket(0)
+	if bucket.rps != 1 {
+		t.Errorf("rps = %v, want 1 for a non-positive input", bucket.rps)
+	}
+}
+
+func TestHostRateLimiterPerHostBuckets(t *testing.T) {
+	limiter := newHostRateLimiter(5)
+
+	limiter.wait("https://a.example.com/search?x=1")
+	limiter.wait("https://b.example.com/search?y=2")
+	limiter.wait("https://a.example.com/search?x=3")
+
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("buckets = %d hosts, want 2 (one per distinct host)", len(limiter.buckets))
+	}
+
+	bucketA, ok := limiter.buckets["a.example.com"]
+	if !ok {
+		t.Fatal("no bucket recorded for a.example.com")
+	}
+	bucketB, ok := limiter.buckets["b.example.com"]
+	if !ok {
+		t.Fatal("no bucket recorded for b.example.com")
+	}
+	if bucketA == bucketB {
+		t.Error("a.example.com and b.example.com share a bucket, want independent buckets")
+	}
+
+	// Repeated waits against the same host must reuse the same bucket so
+	// their rate limit is actually shared rather than reset.
+	limiter.wait("https://a.example.com/search?x=5")
+	if limiter.buckets["a.example.com"] != bucketA {
+		t.Error("a.example.com got a new bucket on a second call, want the original reused")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// starsFlag implements flag.Value so -stars can be repeated on the command
+// line to build up SearchParams.Stars, e.g. -stars 4 -stars 5.
+type starsFlag []int
+
+func (f *starsFlag) String() string {
+	strs := make([]string, len(*f))
+	for i, star := range *f {
+		strs[i] = strconv.Itoa(star)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (f *starsFlag) Set(value string) error {
+	star, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid star rating %q: %v", value, err)
+	}
+	*f = append(*f, star)
+	return nil
+}
+
+// PropertyType narrows a search to one kind of listing (hotel, apartment,
+// hostel) via Booking.com's ht_id filter.
+type PropertyType string
+
+const (
+	PropertyTypeHotel     PropertyType = "hotel"
+	PropertyTypeApartment PropertyType = "apartment"
+	PropertyTypeHostel    PropertyType = "hostel"
+)
+
+// propertyTypeFilterIDs maps a PropertyType to Booking.com's internal
+// ht_id filter value.
+var propertyTypeFilterIDs = map[PropertyType]int{
+	PropertyTypeHotel:     204,
+	PropertyTypeApartment: 201,
+	PropertyTypeHostel:    203,
+}
+
+// SearchParams is the full set of search criteria a scrape can be run with.
+// Country is the only required field. Dates, price bounds, stars and
+// property type are left out of the URL when zero-valued; guest and room
+// counts are always sent, with Adults defaulting to 2 and Rooms to 1.
+type SearchParams struct {
+	Country      string
+	CheckIn      string // YYYY-MM-DD
+	CheckOut     string // YYYY-MM-DD
+	Adults       int
+	Children     int
+	Rooms        int
+	MinPrice     int
+	MaxPrice     int
+	Stars        []int
+	PropertyType PropertyType
+}
+
+// buildBookingURL turns SearchParams into a Booking.com search results URL.
+func buildBookingURL(p SearchParams) string {
+	countryFormatted := strings.ReplaceAll(p.Country, " ", "+")
+	searchURL := fmt.Sprintf("https://www.booking.com/searchresults.html?ss=%s&dest_type=country&order=popularity", countryFormatted)
+
+	if p.CheckIn != "" {
+		searchURL += "&checkin=" + p.CheckIn
+	}
+	if p.CheckOut != "" {
+		searchURL += "&checkout=" + p.CheckOut
+	}
+
+	adults := p.Adults
+	if adults < 1 {
+		adults = 2
+	}
+	searchURL += fmt.Sprintf("&group_adults=%d&group_children=%d", adults, p.Children)
+
+	rooms := p.Rooms
+	if rooms < 1 {
+		rooms = 1
+	}
+	searchURL += fmt.Sprintf("&no_rooms=%d", rooms)
+
+	if len(p.Stars) > 0 {
+		classes := make([]string, len(p.Stars))
+		for i, star := range p.Stars {
+			classes[i] = fmt.Sprintf("class%%3D%d", star)
+		}
+		searchURL += "&nflt=" + strings.Join(classes, "%3B")
+	}
+
+	if p.MinPrice > 0 || p.MaxPrice > 0 {
+		// An unset upper bound must not default to 0, or "--min-price 100" alone
+		// would encode an inverted USD-100-0 range that Booking.com reads
+		// as no results rather than no upper bound.
+		maxPrice := p.MaxPrice
+		if maxPrice <= 0 {
+			maxPrice = math.MaxInt32
+		}
+		searchURL += fmt.Sprintf("&nflt=price%%3DUSD-%d-%d-1", p.MinPrice, maxPrice)
+	}
+
+	if id, ok := propertyTypeFilterIDs[p.PropertyType]; ok {
+		searchURL += fmt.Sprintf("&nflt=ht_id%%3D%d", id)
+	}
+
+	return searchURL
+}
+
+var (
+	ratingPattern      = regexp.MustCompile(`\d+(\.\d+)?`)
+	reviewCountPattern = regexp.MustCompile(`([\d,]+)\s+reviews?`)
+)
+
+// parseReviewScore pulls the rating and review count out of Booking.com's
+// review score blurb, e.g. "Scored 8.5 1,234 reviews Very good".
+func parseReviewScore(text string) (rating, reviewCount string) {
+	rating = ratingPattern.FindString(text)
+	if match := reviewCountPattern.FindStringSubmatch(text); match != nil {
+		reviewCount = match[1]
+	}
+	return rating, reviewCount
+}
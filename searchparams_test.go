@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildBookingURLPriceFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   SearchParams
+		wantNflt string
+		wantNo   string
+	}{
+		{
+			name:     "no bounds set",
+			params:   SearchParams{Country: "France"},
+			wantNflt: "",
+		},
+		{
+			name:     "min only leaves max unbounded",
+			params:   SearchParams{Country: "France", MinPrice: 100},
+			wantNflt: "nflt=price%3DUSD-100-2147483647-1",
+		},
+		{
+			name:     "max only leaves min at zero",
+			params:   SearchParams{Country: "France", MaxPrice: 300},
+			wantNflt: "nflt=price%3DUSD-0-300-1",
+		},
+		{
+			name:     "both bounds set",
+			params:   SearchParams{Country: "France", MinPrice: 100, MaxPrice: 300},
+			wantNflt: "nflt=price%3DUSD-100-300-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := buildBookingURL(tt.params)
+			if tt.wantNflt == "" {
+				if strings.Contains(url, "nflt=price") {
+					t.Fatalf("buildBookingURL(%+v) = %q, want no price filter", tt.params, url)
+				}
+				return
+			}
+			if !strings.Contains(url, tt.wantNflt) {
+				t.Fatalf("buildBookingURL(%+v) = %q, want it to contain %q", tt.params, url, tt.wantNflt)
+			}
+		})
+	}
+}
+
+func TestParseReviewScore(t *testing.T) {
+	rating, reviewCount := parseReviewScore("Scored 8.5 1,234 reviews Very good")
+	if rating != "8.5" {
+		t.Errorf("rating = %q, want %q", rating, "8.5")
+	}
+	if reviewCount != "1,234" {
+		t.Errorf("reviewCount = %q, want %q", reviewCount, "1,234")
+	}
+}
+
+func TestParseReviewScoreMissing(t *testing.T) {
+	rating, reviewCount := parseReviewScore("")
+	if rating != "" || reviewCount != "" {
+		t.Errorf("parseReviewScore(\"\") = (%q, %q), want empty strings", rating, reviewCount)
+	}
+}